@@ -0,0 +1,94 @@
+package edb
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"time"
+)
+
+// Batch buffers a burst of events so they can be written to the
+// underlying file under a single lock acquisition instead of one
+// Append at a time. A Batch is not safe for concurrent use.
+type Batch struct {
+	events []*Event
+}
+
+// Append buffers e without touching the file. Call (*Db).Write to
+// persist the batch.
+func (b *Batch) Append(e *Event) {
+	b.events = append(b.events, e)
+}
+
+// Len reports how many events are currently buffered.
+func (b *Batch) Len() int {
+	return len(b.events)
+}
+
+// Reset empties the batch so it can be reused.
+func (b *Batch) Reset() {
+	b.events = b.events[:0]
+}
+
+// Write appends every event in b to d as a single atomic unit: all of
+// b's events land, or none do. It acquires d's lock once and issues a
+// single write to the underlying file, instead of paying Append's
+// per-event flush cost. When Options.HashIDs is set, it derives each
+// event's Id the same way Append does and rejects the whole batch if
+// that would duplicate an existing event or another event earlier in
+// the same batch. Safe to use by multiple goroutines.
+func (d *Db) Write(b *Batch) error {
+	d.Lock()
+	defer d.Unlock()
+
+	off, err := d.f.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return fmt.Errorf("write: %w", err)
+	}
+
+	now := time.Now()
+	recs := make([]Event, len(b.events))
+	for i, e := range b.events {
+		rec := *e
+		rec.Time = now
+
+		if d.opts.HashIDs {
+			rec.Id = hashEvent(&rec)
+			e.Id = rec.Id
+			if _, dup := d.byId[rec.Id]; dup {
+				return fmt.Errorf("write: duplicate event %s", rec.Id)
+			}
+			for _, prev := range recs[:i] {
+				if prev.Id == rec.Id {
+					return fmt.Errorf("write: duplicate event %s", rec.Id)
+				}
+			}
+		}
+		recs[i] = rec
+	}
+
+	var buf bytes.Buffer
+	offsets := make([]int64, len(recs))
+	for i := range recs {
+		offsets[i] = off + int64(buf.Len())
+		if err := d.codec.Encode(&buf, &recs[i]); err != nil {
+			return fmt.Errorf("write: %w", err)
+		}
+	}
+
+	if _, err := d.f.Write(buf.Bytes()); err != nil {
+		if terr := d.f.Truncate(off); terr != nil {
+			return fmt.Errorf("write: %w (truncate: %s)", err, terr)
+		}
+		if _, serr := d.f.Seek(off, io.SeekStart); serr != nil {
+			return fmt.Errorf("write: %w (seek: %s)", err, serr)
+		}
+		return fmt.Errorf("write: %w", err)
+	}
+
+	for i := range recs {
+		d.index(recs[i], offsets[i])
+		d.notify(recs[i])
+	}
+	return nil
+}
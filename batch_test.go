@@ -0,0 +1,168 @@
+package edb
+
+import (
+	"errors"
+	"io"
+	"io/fs"
+	"path/filepath"
+	"testing"
+)
+
+// failingFile is an in-memory File that fails every Write once more
+// than failAfter bytes have accumulated, so tests can exercise Write's
+// truncate-on-error rollback without touching the real filesystem.
+type failingFile struct {
+	buf       []byte
+	pos       int64
+	failAfter int
+}
+
+func (f *failingFile) Stat() (fs.FileInfo, error) { return nil, errors.New("not implemented") }
+func (f *failingFile) Close() error               { return nil }
+
+func (f *failingFile) Read(p []byte) (int, error) {
+	if f.pos >= int64(len(f.buf)) {
+		return 0, io.EOF
+	}
+	n := copy(p, f.buf[f.pos:])
+	f.pos += int64(n)
+	return n, nil
+}
+
+func (f *failingFile) Seek(offset int64, whence int) (int64, error) {
+	switch whence {
+	case io.SeekStart:
+		f.pos = offset
+	case io.SeekCurrent:
+		f.pos += offset
+	case io.SeekEnd:
+		f.pos = int64(len(f.buf)) + offset
+	}
+	return f.pos, nil
+}
+
+func (f *failingFile) Write(p []byte) (int, error) {
+	if len(f.buf)+len(p) > f.failAfter {
+		return 0, errors.New("simulated write failure")
+	}
+	f.buf = append(f.buf[:f.pos], p...)
+	f.pos += int64(len(p))
+	return len(p), nil
+}
+
+func (f *failingFile) Truncate(size int64) error {
+	f.buf = f.buf[:size]
+	if f.pos > size {
+		f.pos = size
+	}
+	return nil
+}
+
+func TestWriteSucceedsInOneShot(t *testing.T) {
+	d := &Db{f: &failingFile{failAfter: 1 << 20}, codec: CSVCodec{}}
+	if err := d.rebuildIndex(); err != nil {
+		t.Fatalf("rebuild index: %v", err)
+	}
+
+	b := &Batch{}
+	b.Append(&Event{Id: "1", Issuer: "a", Scope: "s", Action: "act"})
+	b.Append(&Event{Id: "2", Issuer: "a", Scope: "s", Action: "act"})
+	if b.Len() != 2 {
+		t.Fatalf("len: got %d, want 2", b.Len())
+	}
+
+	if err := d.Write(b); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	if _, ok := d.Find("1"); !ok {
+		t.Fatalf("find: expected event 1 after write")
+	}
+	if _, ok := d.Find("2"); !ok {
+		t.Fatalf("find: expected event 2 after write")
+	}
+
+	b.Reset()
+	if b.Len() != 0 {
+		t.Fatalf("reset: len %d, want 0", b.Len())
+	}
+}
+
+func TestWriteRollsBackOnFailure(t *testing.T) {
+	f := &failingFile{failAfter: 1}
+	d := &Db{f: f, codec: CSVCodec{}}
+	if err := d.rebuildIndex(); err != nil {
+		t.Fatalf("rebuild index: %v", err)
+	}
+
+	b := &Batch{}
+	b.Append(&Event{Id: "1", Issuer: "a", Scope: "s", Action: "act"})
+	b.Append(&Event{Id: "2", Issuer: "a", Scope: "s", Action: "act"})
+
+	if err := d.Write(b); err == nil {
+		t.Fatalf("write: expected simulated failure to surface")
+	}
+
+	if len(f.buf) != 0 {
+		t.Fatalf("write: file left at %d bytes after rollback, want 0", len(f.buf))
+	}
+	if len(d.order) != 0 {
+		t.Fatalf("write: index has %d entries after a failed write, want 0", len(d.order))
+	}
+	if _, ok := d.Find("1"); ok {
+		t.Fatalf("write: event 1 indexed despite the write failing")
+	}
+
+	// The db must still be usable for subsequent writes after a
+	// rolled-back one (simulate the transient failure clearing up).
+	f.failAfter = 1 << 20
+	if err := d.Append(&Event{Id: "3", Issuer: "a", Scope: "s", Action: "act"}); err != nil {
+		t.Fatalf("append after rollback: %v", err)
+	}
+	if _, ok := d.Find("3"); !ok {
+		t.Fatalf("find: expected event 3 to succeed after a rolled-back batch")
+	}
+}
+
+func TestWriteHashIDsDedup(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "edb.csv")
+	d, err := OpenWithOptions(path, Options{HashIDs: true})
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	defer d.Close()
+
+	e1 := &Event{Issuer: "a", Scope: "s", Action: "act"}
+	e2 := &Event{Issuer: "a", Scope: "s", Action: "act"}
+
+	b := &Batch{}
+	b.Append(e1)
+	b.Append(e2)
+
+	if err := d.Write(b); err == nil {
+		t.Fatalf("write: expected duplicate-id error for two identical events in one batch, got nil (got ids %q and %q)", e1.Id, e2.Id)
+	}
+	if e1.Id == "" {
+		t.Fatalf("write: e1.Id left empty; Batch.Write should hash ids like Append does")
+	}
+
+	// A batch of genuinely distinct events still succeeds and gets
+	// real, non-empty hash ids.
+	b2 := &Batch{}
+	f1 := &Event{Issuer: "a", Scope: "s", Action: "act1"}
+	f2 := &Event{Issuer: "a", Scope: "s", Action: "act2"}
+	b2.Append(f1)
+	b2.Append(f2)
+	if err := d.Write(b2); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	if f1.Id == "" || f2.Id == "" || f1.Id == f2.Id {
+		t.Fatalf("write: expected distinct non-empty hash ids, got %q and %q", f1.Id, f2.Id)
+	}
+
+	// A later batch that repeats an id already persisted is rejected.
+	b3 := &Batch{}
+	b3.Append(&Event{Issuer: "a", Scope: "s", Action: "act1"})
+	if err := d.Write(b3); err == nil {
+		t.Fatalf("write: expected duplicate-id error against an already-persisted event")
+	}
+}
@@ -0,0 +1,123 @@
+package edb
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// Codec encodes and decodes a single Event record. Every record is
+// exactly one line; Encode must terminate its output with a newline,
+// and Decode receives that one line's bytes (newline already
+// stripped).
+type Codec interface {
+	Encode(io.Writer, *Event) error
+	Decode(io.Reader, *Event) error
+	Name() string
+}
+
+// codecs lists every built-in Codec by the name it's recorded under in
+// a file's format header.
+var codecs = map[string]Codec{
+	CSVCodec{}.Name():   CSVCodec{},
+	JSONLCodec{}.Name(): JSONLCodec{},
+}
+
+// formatHeaderPrefix marks the comment line written at file creation
+// time recording which Codec produced the file.
+const formatHeaderPrefix = "# edb-format: "
+
+// detectCodec inspects f's first line to determine which Codec wrote
+// it. A new (empty) file, or one with no recognized header, defaults
+// to CSVCodec — the original format, written before format headers
+// existed. f's position is left wherever the read leaves it; callers
+// that care must seek back themselves.
+func detectCodec(f File) (Codec, error) {
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+
+	line, err := bufio.NewReader(f).ReadString('\n')
+	if err != nil && line == "" {
+		return CSVCodec{}, nil
+	}
+
+	trimmed := strings.TrimRight(line, "\r\n")
+	name, ok := strings.CutPrefix(trimmed, formatHeaderPrefix)
+	if !ok {
+		return CSVCodec{}, nil
+	}
+	c, ok := codecs[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown edb format %q", name)
+	}
+	return c, nil
+}
+
+// CSVCodec is the original edb on-disk format: one comma-separated
+// record per line, with Data appended as trailing fields. It can't
+// represent Data values containing commas or newlines.
+type CSVCodec struct{}
+
+func (CSVCodec) Name() string { return "csv" }
+
+func (CSVCodec) Encode(w io.Writer, e *Event) error {
+	fields := append([]string{
+		e.Id,
+		e.Issuer,
+		e.Scope,
+		e.Action,
+		e.Time.Format(time.RFC3339),
+	}, e.Data...)
+
+	cw := csv.NewWriter(w)
+	if err := cw.Write(fields); err != nil {
+		return err
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+func (CSVCodec) Decode(r io.Reader, e *Event) error {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	cr.TrimLeadingSpace = true
+
+	rec, err := cr.Read()
+	if err != nil {
+		return err
+	}
+	if len(rec) < 4 {
+		return fmt.Errorf("unexpected record %q", rec)
+	}
+	t, err := time.Parse(time.RFC3339, rec[4])
+	if err != nil {
+		return err
+	}
+	e.Id = rec[0]
+	e.Issuer = rec[1]
+	e.Scope = rec[2]
+	e.Action = rec[3]
+	e.Time = t
+	e.Data = append([]string(nil), rec[5:]...)
+	return nil
+}
+
+// JSONLCodec stores one JSON object per line. Unlike CSVCodec it
+// preserves time.Time natively and handles Data values containing
+// commas or newlines without escaping tricks.
+type JSONLCodec struct{}
+
+func (JSONLCodec) Name() string { return "jsonl" }
+
+func (JSONLCodec) Encode(w io.Writer, e *Event) error {
+	return json.NewEncoder(w).Encode(e)
+}
+
+func (JSONLCodec) Decode(r io.Reader, e *Event) error {
+	return json.NewDecoder(r).Decode(e)
+}
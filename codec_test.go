@@ -0,0 +1,94 @@
+package edb
+
+import (
+	"bytes"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestJSONLCodecRoundTrip(t *testing.T) {
+	e := &Event{
+		Id:     "1",
+		Issuer: "alice",
+		Scope:  "s",
+		Action: "act",
+		Time:   time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC),
+		Data:   []string{"a,b", "c\nd"},
+	}
+
+	var buf bytes.Buffer
+	if err := (JSONLCodec{}).Encode(&buf, e); err != nil {
+		t.Fatalf("encode: %v", err)
+	}
+
+	var got Event
+	if err := (JSONLCodec{}).Decode(&buf, &got); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if got.Id != e.Id || got.Issuer != e.Issuer || got.Scope != e.Scope || got.Action != e.Action {
+		t.Fatalf("decode: got %+v, want %+v", got, e)
+	}
+	if !got.Time.Equal(e.Time) {
+		t.Fatalf("decode: time got %v, want %v", got.Time, e.Time)
+	}
+	if len(got.Data) != 2 || got.Data[0] != "a,b" || got.Data[1] != "c\nd" {
+		t.Fatalf("decode: data got %v, want %v", got.Data, e.Data)
+	}
+}
+
+func TestOpenAsJSONLRoundTripsThroughDisk(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "edb.jsonl")
+	d, err := OpenAs(path, JSONLCodec{})
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+
+	e := &Event{Id: "1", Issuer: "alice", Scope: "s", Action: "act", Data: []string{"x,y"}}
+	if err := d.Append(e); err != nil {
+		t.Fatalf("append: %v", err)
+	}
+	if err := d.Close(); err != nil {
+		t.Fatalf("close: %v", err)
+	}
+
+	// Reopening via plain Open must detect the jsonl format header
+	// rather than falling back to CSVCodec, and rebuild the index from
+	// it correctly.
+	d, err = Open(path)
+	if err != nil {
+		t.Fatalf("reopen: %v", err)
+	}
+	defer d.Close()
+
+	got, ok := d.Find("1")
+	if !ok {
+		t.Fatalf("find: expected event 1 after reopen")
+	}
+	if len(got.Data) != 1 || got.Data[0] != "x,y" {
+		t.Fatalf("find: data got %v, want [x,y]", got.Data)
+	}
+}
+
+func TestDetectCodecDefaultsToCSV(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "edb.csv")
+	d, err := Open(path)
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	if err := d.Append(&Event{Id: "1", Issuer: "a", Scope: "s", Action: "act"}); err != nil {
+		t.Fatalf("append: %v", err)
+	}
+	if err := d.Close(); err != nil {
+		t.Fatalf("close: %v", err)
+	}
+
+	d, err = Open(path)
+	if err != nil {
+		t.Fatalf("reopen: %v", err)
+	}
+	defer d.Close()
+	if _, ok := d.Find("1"); !ok {
+		t.Fatalf("find: expected event 1 to be readable via the default CSV codec")
+	}
+}
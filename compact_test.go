@@ -0,0 +1,41 @@
+package edb
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestOpenDiscardsStaleCompactFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "edb.csv")
+
+	d, err := Open(path)
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	if err := d.Append(&Event{Id: "1", Issuer: "a", Scope: "s", Action: "act"}); err != nil {
+		t.Fatalf("append: %v", err)
+	}
+	if err := d.Close(); err != nil {
+		t.Fatalf("close: %v", err)
+	}
+
+	// Simulate a crash mid-Compact: a .compact file was written but
+	// the rename over the original never happened.
+	if err := os.WriteFile(path+compactSuffix, []byte("garbage"), 0644); err != nil {
+		t.Fatalf("write stale compact file: %v", err)
+	}
+
+	d, err = Open(path)
+	if err != nil {
+		t.Fatalf("reopen: %v", err)
+	}
+	defer d.Close()
+
+	if _, err := os.Stat(path + compactSuffix); !os.IsNotExist(err) {
+		t.Fatalf("stale .compact file was not discarded: %v", err)
+	}
+	if _, ok := d.Find("1"); !ok {
+		t.Fatalf("original event lost after discarding stale .compact file")
+	}
+}
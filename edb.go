@@ -1,12 +1,12 @@
 package edb
 
 import (
-	"encoding/csv"
+	"bufio"
 	"errors"
 	"fmt"
 	"io"
 	"io/fs"
-	"os"
+	"strings"
 	"sync"
 	"time"
 )
@@ -25,55 +25,216 @@ type File interface {
 	io.Writer
 	io.Closer
 	io.Seeker
+	Truncate(size int64) error
 }
 
 type Db struct {
 	sync.Mutex
 
-	f File
-	w *csv.Writer
+	f     File
+	path  string
+	codec Codec
+	opts  Options
+
+	// order holds the file offset of every event, in append order, and
+	// backs Range. byId, byIssuer, byScope and byAction index into the
+	// same offsets for point and predicate lookups.
+	order    []int64
+	byId     map[string]int64
+	byIssuer map[string][]int64
+	byScope  map[string][]int64
+	byAction map[string][]int64
+
+	subscribers []chan Event
+	dropped     uint64
 }
 
 // Append a new event to edb. Safe to use by multiple goroutines.
 func (d *Db) Append(e *Event) error {
-	fields := append([]string{
-		e.Id,
-		e.Issuer,
-		e.Scope,
-		e.Action,
-		time.Now().Format(time.RFC3339),
-	}, e.Data...)
+	now := time.Now()
 
 	d.Lock()
 	defer d.Unlock()
 
-	if d.w == nil {
-		d.w = csv.NewWriter(d.f)
+	if d.opts.HashIDs {
+		e.Id = hashEvent(e)
+		if _, dup := d.byId[e.Id]; dup {
+			return fmt.Errorf("append: duplicate event %s", e.Id)
+		}
 	}
-	if err := d.w.Write(fields); err != nil {
+
+	rec := *e
+	rec.Time = now
+
+	off, err := d.f.Seek(0, io.SeekCurrent)
+	if err != nil {
 		return fmt.Errorf("append: %w", err)
 	}
-	d.w.Flush()
+	if err := d.codec.Encode(d.f, &rec); err != nil {
+		return fmt.Errorf("append: %w", err)
+	}
+
+	d.index(rec, off)
+	d.notify(rec)
 	return nil
 }
 
+// index records e's offset in the primary and secondary indexes. Callers
+// must hold d's lock.
+func (d *Db) index(e Event, offset int64) {
+	d.order = append(d.order, offset)
+	d.byId[e.Id] = offset
+	d.byIssuer[e.Issuer] = append(d.byIssuer[e.Issuer], offset)
+	d.byScope[e.Scope] = append(d.byScope[e.Scope], offset)
+	d.byAction[e.Action] = append(d.byAction[e.Action], offset)
+}
+
+// Find looks up an event by id in O(1) via the primary index.
 func (d *Db) Find(eid string) (*Event, bool) {
 	d.Lock()
 	defer d.Unlock()
 
-	var e Event
-	var ok bool
-	d.Revive(func(next Event) error {
-		if next.Id != eid {
-			return nil
+	off, ok := d.byId[eid]
+	if !ok {
+		return &Event{}, false
+	}
+	e, err := d.readAt(off)
+	if err != nil {
+		return &Event{}, false
+	}
+	return &e, true
+}
+
+// FindByIssuer returns every event recorded under issuer, in append order.
+func (d *Db) FindByIssuer(issuer string) []*Event {
+	d.Lock()
+	defer d.Unlock()
+	return d.findAll(d.byIssuer[issuer])
+}
+
+// FindByScope returns every event recorded under scope, in append order.
+func (d *Db) FindByScope(scope string) []*Event {
+	d.Lock()
+	defer d.Unlock()
+	return d.findAll(d.byScope[scope])
+}
+
+// findAll reads every offset in offs into an Event slice. Callers must
+// hold d's lock.
+func (d *Db) findAll(offs []int64) []*Event {
+	events := make([]*Event, 0, len(offs))
+	for _, off := range offs {
+		e, err := d.readAt(off)
+		if err != nil {
+			continue
 		}
+		events = append(events, &e)
+	}
+	return events
+}
 
-		e, ok = next, true
-		e.Data = make([]string, len(next.Data))
-		copy(e.Data, next.Data)
+// Range calls h for every event whose Time falls within [start, end],
+// in append order. It relies on events being appended in non-decreasing
+// time order and stops as soon as it sees one past end.
+func (d *Db) Range(start, end time.Time, h func(Event) error) error {
+	d.Lock()
+	defer d.Unlock()
+
+	for _, off := range d.order {
+		e, err := d.readAt(off)
+		if err != nil {
+			return fmt.Errorf("range: %w", err)
+		}
+		if e.Time.Before(start) {
+			continue
+		}
+		if e.Time.After(end) {
+			break
+		}
+		if err := h(e); err != nil {
+			return fmt.Errorf("range: %w", err)
+		}
+	}
+	return nil
+}
+
+// readAt decodes the single event record at offset. Callers must hold
+// d's lock.
+func (d *Db) readAt(offset int64) (Event, error) {
+	if _, err := d.f.Seek(offset, io.SeekStart); err != nil {
+		return Event{}, fmt.Errorf("read at %d: %w", offset, err)
+	}
+	defer d.f.Seek(0, io.SeekEnd)
+
+	line, err := bufio.NewReader(d.f).ReadString('\n')
+	if err != nil && line == "" {
+		return Event{}, fmt.Errorf("read at %d: %w", offset, err)
+	}
+
+	var e Event
+	if derr := d.codec.Decode(strings.NewReader(strings.TrimRight(line, "\r\n")), &e); derr != nil {
+		return Event{}, fmt.Errorf("read at %d: %w", offset, derr)
+	}
+	return e, nil
+}
+
+// rebuildIndex discards and rebuilds every index by streaming through
+// the file once, tracking each record's byte offset as it goes. Callers
+// must hold d's lock.
+func (d *Db) rebuildIndex() error {
+	d.order = nil
+	d.byId = make(map[string]int64)
+	d.byIssuer = make(map[string][]int64)
+	d.byScope = make(map[string][]int64)
+	d.byAction = make(map[string][]int64)
+
+	if _, err := d.f.Seek(0, io.SeekStart); err != nil {
+		return fmt.Errorf("rebuild index: %w", err)
+	}
+	defer d.f.Seek(0, io.SeekEnd)
+
+	return d.eachLine(func(line string, offset int64) error {
+		var e Event
+		if err := d.codec.Decode(strings.NewReader(line), &e); err != nil {
+			return fmt.Errorf("rebuild index: offset %d: %w", offset, err)
+		}
+		d.index(e, offset)
 		return nil
 	})
-	return &e, ok
+}
+
+// eachLine streams the file from its current position, invoking h with
+// every non-blank, non-comment line (newline stripped) and its byte
+// offset. Callers must hold d's lock and position d.f themselves.
+func (d *Db) eachLine(h func(line string, offset int64) error) error {
+	br := bufio.NewReader(d.f)
+	var offset int64
+	for {
+		raw, err := br.ReadString('\n')
+		size := int64(len(raw))
+
+		trimmed := strings.TrimRight(raw, "\r\n")
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			offset += size
+			if err != nil {
+				break
+			}
+			continue
+		}
+
+		if herr := h(trimmed, offset); herr != nil {
+			return herr
+		}
+
+		offset += size
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			return err
+		}
+	}
+	return nil
 }
 
 func (d *Db) Dump(w io.Writer) error {
@@ -93,57 +254,30 @@ func (d *Db) Revive(h func(e Event) error) error {
 	d.f.Seek(0, io.SeekStart)
 	defer d.f.Seek(0, io.SeekEnd)
 
-	r := csv.NewReader(d.f)
-	r.Comment = '#'
-	r.FieldsPerRecord = -1
-	r.TrimLeadingSpace = true
-	r.ReuseRecord = true
-
-	var e Event
-	for line := 1; ; line++ {
-		rec, err := r.Read()
-		if err != nil {
-			if errors.Is(err, io.EOF) {
-				return nil
-			}
-			return fmt.Errorf("revive: %w", err)
+	return d.eachLine(func(line string, offset int64) error {
+		var e Event
+		if err := d.codec.Decode(strings.NewReader(line), &e); err != nil {
+			return fmt.Errorf("revive: offset %d: %w", offset, err)
 		}
-		if len(rec) < 4 {
-			return fmt.Errorf("revive: line %d: unexpected record %q", line, rec)
+		if d.opts.VerifyOnRevive && hashEvent(&e) != e.Id {
+			return fmt.Errorf("revive: offset %d: hash mismatch for event %s", offset, e.Id)
 		}
-		e.Id = rec[0]
-		e.Issuer = rec[1]
-		e.Scope = rec[2]
-		e.Action = rec[3]
-		t, err := time.Parse(time.RFC3339, rec[4])
-		if err != nil {
-			return fmt.Errorf("revive: line %d: %w", line, err)
-		}
-		e.Time = t
-		e.Data = rec[5:]
 		if err := h(e); err != nil {
 			return fmt.Errorf("revive: %w", err)
 		}
-	}
+		return nil
+	})
 }
 
 func (db *Db) Close() error {
 	db.Lock()
 	defer db.Unlock()
 
-	if w := db.w; w != nil {
-		db.w.Flush()
-		db.w = nil
-	}
 	db.f.Close()
 	db.f = nil
 	return nil
 }
 
 func Open(p string) (*Db, error) {
-	f, err := os.OpenFile(p, os.O_RDWR|os.O_CREATE, 0755)
-	if err != nil {
-		return nil, fmt.Errorf("from path: %w", err)
-	}
-	return &Db{f: f}, nil
+	return OpenWithOptions(p, Options{})
 }
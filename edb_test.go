@@ -0,0 +1,94 @@
+package edb
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestFindByIdIssuerScope(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "edb.csv")
+	d, err := Open(path)
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+
+	events := []*Event{
+		{Id: "1", Issuer: "alice", Scope: "s1", Action: "act"},
+		{Id: "2", Issuer: "bob", Scope: "s1", Action: "act"},
+		{Id: "3", Issuer: "alice", Scope: "s2", Action: "act"},
+	}
+	for _, e := range events {
+		if err := d.Append(e); err != nil {
+			t.Fatalf("append %s: %v", e.Id, err)
+		}
+	}
+
+	if _, ok := d.Find("1"); !ok {
+		t.Fatalf("find: expected event 1 to be found")
+	}
+	if _, ok := d.Find("missing"); ok {
+		t.Fatalf("find: expected no event for unknown id")
+	}
+
+	if got := d.FindByIssuer("alice"); len(got) != 2 {
+		t.Fatalf("findByIssuer: got %d events, want 2", len(got))
+	}
+	if got := d.FindByScope("s1"); len(got) != 2 {
+		t.Fatalf("findByScope: got %d events, want 2", len(got))
+	}
+	if got := d.FindByScope("nope"); len(got) != 0 {
+		t.Fatalf("findByScope: got %d events, want 0", len(got))
+	}
+
+	// The index should rebuild correctly after a reopen too.
+	if err := d.Close(); err != nil {
+		t.Fatalf("close: %v", err)
+	}
+	d, err = Open(path)
+	if err != nil {
+		t.Fatalf("reopen: %v", err)
+	}
+	defer d.Close()
+	if _, ok := d.Find("3"); !ok {
+		t.Fatalf("find after reopen: expected event 3 to be found")
+	}
+}
+
+func TestRange(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "edb.csv")
+	d, err := Open(path)
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	defer d.Close()
+
+	base := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	var seen []string
+	if err := d.Range(base, base.Add(time.Hour), func(e Event) error {
+		seen = append(seen, e.Id)
+		return nil
+	}); err != nil {
+		t.Fatalf("range on empty db: %v", err)
+	}
+	if len(seen) != 0 {
+		t.Fatalf("range on empty db: got %v, want none", seen)
+	}
+
+	for _, id := range []string{"a", "b", "c"} {
+		if err := d.Append(&Event{Id: id, Issuer: "i", Scope: "s", Action: "act"}); err != nil {
+			t.Fatalf("append %s: %v", id, err)
+		}
+	}
+
+	var got []string
+	if err := d.Range(time.Time{}, time.Now().Add(time.Hour), func(e Event) error {
+		got = append(got, e.Id)
+		return nil
+	}); err != nil {
+		t.Fatalf("range: %v", err)
+	}
+	if len(got) != 3 {
+		t.Fatalf("range: got %v, want 3 events", got)
+	}
+}
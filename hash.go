@@ -0,0 +1,124 @@
+package edb
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"strings"
+)
+
+// Options configures optional behavior of a Db opened via
+// OpenWithOptions.
+type Options struct {
+	// HashIDs derives each event's Id from a content hash (see
+	// hashEvent) instead of trusting the caller-supplied Id, and
+	// rejects Appends that would duplicate an existing hash.
+	HashIDs bool
+
+	// VerifyOnRevive recomputes and checks each event's content hash
+	// while reviving, returning an error on the first mismatch. It
+	// only guards Revive itself: Find, FindByIssuer, FindByScope and
+	// Range all read through the offset index instead, so they get no
+	// tamper protection from this option. Call Verify for that.
+	VerifyOnRevive bool
+}
+
+// OpenWithOptions opens or creates the edb file at p, same as Open, but
+// with the given Options in effect. The on-disk format is detected
+// from the file's header, defaulting to CSVCodec for new or
+// header-less files; use OpenAs to pick the format explicitly.
+func OpenWithOptions(p string, opts Options) (*Db, error) {
+	return open(p, nil, opts)
+}
+
+// OpenAs opens or creates the edb file at p, forcing codec as its
+// on-disk format instead of detecting one from the file's header.
+func OpenAs(p string, codec Codec) (*Db, error) {
+	return open(p, codec, Options{})
+}
+
+func open(p string, codec Codec, opts Options) (*Db, error) {
+	// A leftover .compact file means a prior Compact was interrupted
+	// before it could rename over p; the original file is still
+	// intact, so the half-written compaction is simply discarded.
+	if err := os.Remove(p + compactSuffix); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("from path: %w", err)
+	}
+
+	f, err := os.OpenFile(p, os.O_RDWR|os.O_CREATE, 0755)
+	if err != nil {
+		return nil, fmt.Errorf("from path: %w", err)
+	}
+
+	info, err := f.Stat()
+	if err != nil {
+		return nil, fmt.Errorf("from path: %w", err)
+	}
+	empty := info.Size() == 0
+
+	if codec == nil {
+		codec, err = detectCodec(f)
+		if err != nil {
+			return nil, fmt.Errorf("from path: %w", err)
+		}
+	}
+	if empty {
+		if _, err := f.Write([]byte(formatHeaderPrefix + codec.Name() + "\n")); err != nil {
+			return nil, fmt.Errorf("from path: %w", err)
+		}
+	}
+
+	d := &Db{f: f, path: p, opts: opts, codec: codec}
+	if err := d.rebuildIndex(); err != nil {
+		return nil, fmt.Errorf("from path: %w", err)
+	}
+	return d, nil
+}
+
+// hashEvent computes a stable content hash over e's issuer, scope,
+// action and data, hex-encoded. It deliberately excludes Time: Time is
+// server-assigned at Append/Write time, and its on-disk precision
+// (CSVCodec stores it as RFC3339, i.e. one-second resolution) would
+// otherwise make two genuinely distinct events appended within the
+// same second collide, and make a hash recomputed after a round trip
+// through the file disagree with the one computed before it was
+// written. With Options.HashIDs this hash doubles as the event's Id;
+// with Options.VerifyOnRevive it is recomputed on read to detect
+// tampering.
+func hashEvent(e *Event) string {
+	h := sha256.New()
+	fmt.Fprintf(h, "%s\x00%s\x00%s", e.Issuer, e.Scope, e.Action)
+	for _, d := range e.Data {
+		fmt.Fprintf(h, "\x00%s", d)
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// Verify streams the whole file and recomputes each event's content
+// hash, returning an error describing the first corrupted record and
+// its byte offset. It does not require Options.HashIDs or
+// Options.VerifyOnRevive to have been set at Open time.
+func (d *Db) Verify() error {
+	d.Lock()
+	defer d.Unlock()
+
+	if _, err := d.f.Seek(0, io.SeekStart); err != nil {
+		return fmt.Errorf("verify: %w", err)
+	}
+	defer d.f.Seek(0, io.SeekEnd)
+
+	return d.eachLine(func(line string, offset int64) error {
+		var e Event
+		if err := d.codec.Decode(strings.NewReader(line), &e); err != nil {
+			return fmt.Errorf("verify: offset %d: %w", offset, err)
+		}
+		if hashEvent(&e) != e.Id {
+			return fmt.Errorf("verify: offset %d: hash mismatch for event %s", offset, e.Id)
+		}
+		return nil
+	})
+}
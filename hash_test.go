@@ -0,0 +1,119 @@
+package edb
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestVerifyCatchesTampering(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "edb.csv")
+	d, err := OpenWithOptions(path, Options{HashIDs: true})
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+
+	e := &Event{Issuer: "a", Scope: "s", Action: "act"}
+	if err := d.Append(e); err != nil {
+		t.Fatalf("append: %v", err)
+	}
+	if err := d.Close(); err != nil {
+		t.Fatalf("close: %v", err)
+	}
+
+	if err := verifyOK(path); err != nil {
+		t.Fatalf("verify before tampering: %v", err)
+	}
+
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read file: %v", err)
+	}
+	tampered := []byte(strings.ReplaceAll(string(raw), "act", "evil"))
+	if err := os.WriteFile(path, tampered, 0644); err != nil {
+		t.Fatalf("write tampered file: %v", err)
+	}
+
+	if err := verifyOK(path); err == nil {
+		t.Fatalf("verify: expected hash mismatch after tampering, got nil")
+	}
+}
+
+// verifyOK opens path read-write (Verify needs no special options) and
+// runs Verify, closing the db before returning.
+func verifyOK(path string) error {
+	d, err := Open(path)
+	if err != nil {
+		return err
+	}
+	defer d.Close()
+	return d.Verify()
+}
+
+// TestHashIDsDoesNotCollideWithinSameSecond guards against hashEvent
+// folding in Time at RFC3339 (one-second) resolution: two back-to-back
+// Appends of distinct events are fast enough to land in the same
+// wall-clock second, and must not be rejected as duplicates of each
+// other just because their Data differs rather than their Time.
+func TestHashIDsDoesNotCollideWithinSameSecond(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "edb.csv")
+	d, err := OpenWithOptions(path, Options{HashIDs: true})
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	defer d.Close()
+
+	e1 := &Event{Issuer: "a", Scope: "s", Action: "act", Data: []string{"1"}}
+	e2 := &Event{Issuer: "a", Scope: "s", Action: "act", Data: []string{"2"}}
+	if err := d.Append(e1); err != nil {
+		t.Fatalf("append e1: %v", err)
+	}
+	if err := d.Append(e2); err != nil {
+		t.Fatalf("append e2: %v", err)
+	}
+	if e1.Id == e2.Id {
+		t.Fatalf("append: distinct events got the same hash id %q", e1.Id)
+	}
+	if _, ok := d.Find(e1.Id); !ok {
+		t.Fatalf("find: expected e1 to be present")
+	}
+	if _, ok := d.Find(e2.Id); !ok {
+		t.Fatalf("find: expected e2 to be present")
+	}
+}
+
+func TestReviveVerifyOnReviveCatchesTampering(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "edb.csv")
+	d, err := OpenWithOptions(path, Options{HashIDs: true})
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+
+	if err := d.Append(&Event{Issuer: "a", Scope: "s", Action: "act"}); err != nil {
+		t.Fatalf("append: %v", err)
+	}
+	if err := d.Close(); err != nil {
+		t.Fatalf("close: %v", err)
+	}
+
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read file: %v", err)
+	}
+	tampered := []byte(strings.ReplaceAll(string(raw), "act", "evil"))
+	if err := os.WriteFile(path, tampered, 0644); err != nil {
+		t.Fatalf("write tampered file: %v", err)
+	}
+
+	d, err = OpenWithOptions(path, Options{VerifyOnRevive: true})
+	if err != nil {
+		t.Fatalf("reopen: %v", err)
+	}
+	defer d.Close()
+
+	err = d.Revive(func(e Event) error { return nil })
+	if err == nil {
+		t.Fatalf("revive: expected hash mismatch with VerifyOnRevive, got nil")
+	}
+}
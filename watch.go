@@ -0,0 +1,124 @@
+package edb
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync/atomic"
+	"time"
+)
+
+// watchBuffer is the per-subscriber channel size. A subscriber that
+// falls this far behind has events dropped rather than blocking
+// Append.
+const watchBuffer = 16
+
+// Watch returns a channel that receives a copy of every event appended
+// to d after the call returns. The channel is closed once ctx is done.
+func (d *Db) Watch(ctx context.Context) (<-chan Event, error) {
+	d.Lock()
+	if d.f == nil {
+		d.Unlock()
+		return nil, fmt.Errorf("watch: db is closed")
+	}
+	ch := make(chan Event, watchBuffer)
+	d.subscribers = append(d.subscribers, ch)
+	d.Unlock()
+
+	go func() {
+		<-ctx.Done()
+		d.unsubscribe(ch)
+	}()
+	return ch, nil
+}
+
+// unsubscribe removes and closes ch.
+func (d *Db) unsubscribe(ch chan Event) {
+	d.Lock()
+	defer d.Unlock()
+
+	for i, c := range d.subscribers {
+		if c == ch {
+			d.subscribers = append(d.subscribers[:i], d.subscribers[i+1:]...)
+			break
+		}
+	}
+	close(ch)
+}
+
+// notify fans e out to every subscriber. It never blocks: a subscriber
+// too slow to keep up has the event dropped and Dropped incremented
+// instead. Callers must hold d's lock.
+func (d *Db) notify(e Event) {
+	for _, ch := range d.subscribers {
+		select {
+		case ch <- e:
+		default:
+			atomic.AddUint64(&d.dropped, 1)
+			log.Printf("edb: watch: subscriber too slow, dropped event %s", e.Id)
+		}
+	}
+}
+
+// Dropped reports how many events have been dropped for slow Watch
+// subscribers since Open.
+func (d *Db) Dropped() uint64 {
+	return atomic.LoadUint64(&d.dropped)
+}
+
+// Follow first replays every event at or after from via the same
+// in-memory order index Range uses, then seamlessly transitions to
+// live tailing, calling h for each event in turn. It returns when ctx
+// is done or h returns an error.
+//
+// Unlike Range, Follow never calls h while holding d's lock: the
+// historical replay is read into a local slice in one atomic pass
+// under the lock (so a concurrent Compact can't swap offsets out from
+// under it mid-read), and h is then invoked entirely outside the lock.
+// That keeps a handler that calls back into d (e.g. d.Find) from
+// deadlocking on sync.Mutex's non-reentrancy, and keeps a slow handler
+// from freezing every other Append/Find/Compact/Watch caller for as
+// long as h takes to run.
+func (d *Db) Follow(ctx context.Context, from time.Time, h func(Event) error) error {
+	d.Lock()
+	if d.f == nil {
+		d.Unlock()
+		return fmt.Errorf("follow: db is closed")
+	}
+	history := make([]Event, 0, len(d.order))
+	for _, off := range d.order {
+		e, err := d.readAt(off)
+		if err != nil {
+			d.Unlock()
+			return fmt.Errorf("follow: %w", err)
+		}
+		history = append(history, e)
+	}
+	ch := make(chan Event, watchBuffer)
+	d.subscribers = append(d.subscribers, ch)
+	d.Unlock()
+	defer d.unsubscribe(ch)
+
+	for _, e := range history {
+		if e.Time.Before(from) {
+			continue
+		}
+		if err := h(e); err != nil {
+			return fmt.Errorf("follow: %w", err)
+		}
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case e, ok := <-ch:
+			if !ok {
+				return nil
+			}
+			if err := h(e); err != nil {
+				return fmt.Errorf("follow: %w", err)
+			}
+		}
+	}
+}
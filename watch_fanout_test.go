@@ -0,0 +1,97 @@
+package edb
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestWatchFanOutToMultipleSubscribers(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "edb.csv")
+	d, err := Open(path)
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	defer d.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	ch1, err := d.Watch(ctx)
+	if err != nil {
+		t.Fatalf("watch 1: %v", err)
+	}
+	ch2, err := d.Watch(ctx)
+	if err != nil {
+		t.Fatalf("watch 2: %v", err)
+	}
+
+	if err := d.Append(&Event{Id: "1", Issuer: "a", Scope: "s", Action: "act"}); err != nil {
+		t.Fatalf("append: %v", err)
+	}
+
+	for _, ch := range []<-chan Event{ch1, ch2} {
+		select {
+		case e := <-ch:
+			if e.Id != "1" {
+				t.Fatalf("watch: got event %q, want 1", e.Id)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("watch: timed out waiting for fan-out")
+		}
+	}
+}
+
+func TestWatchDropsForSlowSubscriber(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "edb.csv")
+	d, err := Open(path)
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	defer d.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// Never drained, so it fills up and every event past watchBuffer
+	// gets dropped rather than blocking Append.
+	if _, err := d.Watch(ctx); err != nil {
+		t.Fatalf("watch: %v", err)
+	}
+
+	before := d.Dropped()
+	for i := 0; i < watchBuffer+5; i++ {
+		if err := d.Append(&Event{Issuer: "a", Scope: "s", Action: "act"}); err != nil {
+			t.Fatalf("append %d: %v", i, err)
+		}
+	}
+	if got := d.Dropped(); got <= before {
+		t.Fatalf("dropped: got %d, want more than %d", got, before)
+	}
+}
+
+func TestUnsubscribeClosesChannel(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "edb.csv")
+	d, err := Open(path)
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	defer d.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	ch, err := d.Watch(ctx)
+	if err != nil {
+		t.Fatalf("watch: %v", err)
+	}
+	cancel()
+
+	select {
+	case _, ok := <-ch:
+		if ok {
+			t.Fatalf("watch: expected channel to be closed after cancel")
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("watch: timed out waiting for channel close after cancel")
+	}
+}